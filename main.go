@@ -16,13 +16,17 @@ var logger = log.New(os.Stdout, "", log.LstdFlags)
 const DEBUG = false
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	outDir := "out"
 	if DEBUG {
 		outDir = "debug_out"
 	}
-	err := os.MkdirAll(outDir, 0755)
-	if err != nil {
-		logger.Fatal(err)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		logger.Println(err)
+		return 1
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -34,9 +38,13 @@ func main() {
 	trackerProject := os.Getenv("TRACKER_PROJECT")
 	trackerToken := os.Getenv("TRACKER_TOKEN")
 	clickupToken := os.Getenv("CLICKUP_TOKEN")
+	exitCode := 0
 	if trackerProject != "" && trackerToken != "" {
 		logger.Println("TRACKER_PROJECT and TRACKER_TOKEN detected, doing tracker export")
-		tracker.Export(ctx, trackerToken, trackerProject, outDir)
+		if err := tracker.Export(ctx, trackerToken, trackerProject, outDir); err != nil {
+			logger.Println(err)
+			exitCode = 1
+		}
 	}
 
 	if clickupToken != "" {
@@ -45,6 +53,7 @@ func main() {
 	}
 
 	logger.Println("done")
+	return exitCode
 }
 
 func handleInterruptSignals(done func()) {
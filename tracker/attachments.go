@@ -0,0 +1,331 @@
+package tracker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const defaultAttachmentWorkers = 4
+const maxAttachmentAttempts = 5
+
+// AttachmentJob describes a single comment attachment to fetch and persist to disk.
+type AttachmentJob struct {
+	ID           string
+	URL          string
+	Filename     string
+	ExpectedSize int64
+	SHA256       string
+}
+
+// AttachmentProgress is an aggregate snapshot of an in-flight download batch.
+type AttachmentProgress struct {
+	BytesDone  int64
+	BytesTotal int64
+	ActiveJobs int
+}
+
+// AttachmentResult records where a job ended up on disk, or why it failed.
+type AttachmentResult struct {
+	Job  AttachmentJob
+	Path string
+	Err  error
+}
+
+type manifestEntry struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256,omitempty"`
+	Size     int64  `json:"size"`
+}
+
+// AttachmentManager downloads comment attachments with a bounded worker pool,
+// deduplicating by attachment id and resuming partial downloads via HTTP Range.
+type AttachmentManager struct {
+	httpClient *http.Client
+	outDir     string
+	workers    int
+	progress   chan AttachmentProgress
+	reporter   ProgressReporter
+
+	mu       sync.Mutex
+	seen     map[string]*AttachmentResult
+	manifest map[string]manifestEntry
+
+	bytesTotal int64
+	bytesDone  int64
+	active     int
+}
+
+func newAttachmentManager(client *resty.Client, outDir string) *AttachmentManager {
+	workers := defaultAttachmentWorkers
+	if v := os.Getenv("ATTACHMENT_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			workers = n
+		}
+	}
+	return &AttachmentManager{
+		httpClient: client.GetClient(),
+		outDir:     filepath.Join(outDir, "attachments"),
+		workers:    workers,
+		progress:   make(chan AttachmentProgress, 16),
+		seen:       map[string]*AttachmentResult{},
+		manifest:   map[string]manifestEntry{},
+	}
+}
+
+// Progress returns the channel that receives aggregate download progress updates.
+func (m *AttachmentManager) Progress() <-chan AttachmentProgress {
+	return m.progress
+}
+
+// SetReporter wires the manager into the same ProgressReporter used by the
+// rest of Export, so attachment transfers show up alongside the other bars.
+func (m *AttachmentManager) SetReporter(reporter ProgressReporter) {
+	m.reporter = reporter
+}
+
+// Download fetches the given jobs with a bounded pool of workers, skipping any
+// attachment id already seen in this run (whether by this call or an earlier
+// one). It returns once every job has been attempted or ctx is canceled;
+// individual failures are collected in the result slice rather than aborting
+// the whole batch. The returned slice always has one entry per job in jobs, in
+// order, even for ids that were already downloaded by an earlier call or
+// duplicated within jobs itself: those are filled in from the recorded result
+// rather than dropped. Jobs never started because ctx was canceled carry
+// ctx's error.
+func (m *AttachmentManager) Download(ctx context.Context, jobs []AttachmentJob) []AttachmentResult {
+	pending := make([]AttachmentJob, 0, len(jobs))
+	queuedInThisCall := map[string]bool{}
+	m.mu.Lock()
+	for _, j := range jobs {
+		if _, ok := m.seen[j.ID]; ok {
+			continue
+		}
+		if queuedInThisCall[j.ID] {
+			continue
+		}
+		queuedInThisCall[j.ID] = true
+		m.bytesTotal += j.ExpectedSize
+		pending = append(pending, j)
+	}
+	m.mu.Unlock()
+
+	queue := make(chan AttachmentJob)
+	var wg sync.WaitGroup
+	for i := 0; i < m.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				m.mu.Lock()
+				m.active++
+				m.mu.Unlock()
+
+				res := m.downloadOne(ctx, job)
+
+				m.mu.Lock()
+				m.active--
+				m.seen[job.ID] = &res
+				if res.Err == nil {
+					m.manifest[job.ID] = manifestEntry{ID: job.ID, Filename: job.Filename, Path: res.Path, SHA256: job.SHA256, Size: job.ExpectedSize}
+				}
+				m.mu.Unlock()
+			}
+		}()
+	}
+
+	go func() {
+		defer close(queue)
+		for _, job := range pending {
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	results := make([]AttachmentResult, len(jobs))
+	m.mu.Lock()
+	for i, job := range jobs {
+		if res := m.seen[job.ID]; res != nil {
+			results[i] = *res
+		} else {
+			results[i] = AttachmentResult{Job: job, Err: ctx.Err()}
+		}
+	}
+	m.mu.Unlock()
+	return results
+}
+
+func (m *AttachmentManager) downloadOne(ctx context.Context, job AttachmentJob) AttachmentResult {
+	destDir := filepath.Join(m.outDir, job.ID)
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return AttachmentResult{Job: job, Err: fmt.Errorf("attachment %s: creating dir: %w", job.ID, err)}
+	}
+	dest := filepath.Join(destDir, job.Filename)
+
+	backoff := time.Second
+	for attempt := 1; attempt <= maxAttachmentAttempts; attempt++ {
+		n, err := m.fetchToFile(ctx, job, dest)
+		if err == nil {
+			m.reportProgress(n)
+			return AttachmentResult{Job: job, Path: dest}
+		}
+		if errors.Is(err, context.Canceled) {
+			return AttachmentResult{Job: job, Err: err}
+		}
+
+		var httpErr *attachmentHTTPError
+		if !errors.As(err, &httpErr) {
+			return AttachmentResult{Job: job, Err: err}
+		}
+
+		wait := backoff
+		if httpErr.RetryAfter > 0 {
+			wait = httpErr.RetryAfter
+		}
+		logger.Printf("attachment %s: %v, retrying in %s (attempt %d/%d)", job.ID, err, wait, attempt, maxAttachmentAttempts)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return AttachmentResult{Job: job, Err: ctx.Err()}
+		}
+		backoff *= 2
+	}
+	return AttachmentResult{Job: job, Err: fmt.Errorf("attachment %s: giving up after %d attempts", job.ID, maxAttachmentAttempts)}
+}
+
+type attachmentHTTPError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *attachmentHTTPError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Status)
+}
+
+// fetchToFile downloads job.URL into dest, resuming from the end of an
+// existing partial file via a Range request when one is present.
+func (m *AttachmentManager) fetchToFile(ctx context.Context, job AttachmentJob, dest string) (int64, error) {
+	var resumeFrom int64
+	if fi, err := os.Stat(dest); err == nil {
+		resumeFrom = fi.Size()
+		if job.ExpectedSize > 0 && resumeFrom == job.ExpectedSize {
+			return 0, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return 0, &attachmentHTTPError{Status: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		if resp.StatusCode >= 500 {
+			return 0, &attachmentHTTPError{Status: resp.StatusCode}
+		}
+		return 0, fmt.Errorf("attachment download failed: unexpected status %d", resp.StatusCode)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if resp.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+		resumeFrom = 0
+	}
+	f, err := os.OpenFile(dest, flags, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var reader io.Reader = resp.Body
+	var hasher = sha256.New()
+	if job.SHA256 != "" {
+		reader = io.TeeReader(resp.Body, hasher)
+	}
+
+	n, err := io.Copy(f, reader)
+	if err != nil {
+		return n, err
+	}
+
+	if job.SHA256 != "" {
+		if sum := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(sum, job.SHA256) {
+			return n, fmt.Errorf("attachment %s: checksum mismatch (got %s, want %s)", job.ID, sum, job.SHA256)
+		}
+	}
+	return n, nil
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func (m *AttachmentManager) reportProgress(n int64) {
+	m.mu.Lock()
+	m.bytesDone += n
+	snap := AttachmentProgress{BytesDone: m.bytesDone, BytesTotal: m.bytesTotal, ActiveJobs: m.active}
+	m.mu.Unlock()
+
+	select {
+	case m.progress <- snap:
+	default:
+	}
+	if m.reporter != nil {
+		m.reporter.UpdateBytes("attachments", snap.BytesDone, snap.BytesTotal, snap.ActiveJobs)
+	}
+}
+
+// WriteManifest persists the attachment id -> local path mapping accumulated
+// across every Download call made against this manager.
+func (m *AttachmentManager) WriteManifest(file string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reporter != nil {
+		m.reporter.Done("attachments")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return ErrIO{Op: "mkdir", Path: filepath.Dir(file), Err: err}
+	}
+	return saveAsJSON(m.manifest, file)
+}
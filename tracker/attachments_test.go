@@ -0,0 +1,165 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countingRoundTripper answers every request with a fixed body and counts how
+// many requests it actually saw, so a test can assert a download was (or
+// wasn't) deduplicated away before reaching the network.
+type countingRoundTripper struct {
+	mu    sync.Mutex
+	calls int
+	body  string
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+func newTestAttachmentManager(t *testing.T, rt http.RoundTripper) *AttachmentManager {
+	return &AttachmentManager{
+		httpClient: &http.Client{Transport: rt},
+		outDir:     t.TempDir(),
+		workers:    2,
+		progress:   make(chan AttachmentProgress, 16),
+		seen:       map[string]*AttachmentResult{},
+		manifest:   map[string]manifestEntry{},
+	}
+}
+
+func TestAttachmentManagerDownload_DedupsWithinABatch(t *testing.T) {
+	rt := &countingRoundTripper{body: "attachment body"}
+	m := newTestAttachmentManager(t, rt)
+	job := AttachmentJob{ID: "42", URL: "http://fake.invalid/42", Filename: "f.bin"}
+
+	results := m.Download(context.Background(), []AttachmentJob{job, job})
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for 2 input jobs, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result %d: unexpected error: %v", i, r.Err)
+		}
+	}
+	if results[0].Path != results[1].Path || results[0].Path == "" {
+		t.Errorf("expected both duplicate jobs to resolve to the same non-empty path, got %q and %q", results[0].Path, results[1].Path)
+	}
+	if rt.calls != 1 {
+		t.Errorf("expected exactly 1 network call for 2 duplicate jobs in one batch, got %d", rt.calls)
+	}
+}
+
+func TestAttachmentManagerDownload_DedupsAcrossCalls(t *testing.T) {
+	rt := &countingRoundTripper{body: "attachment body"}
+	m := newTestAttachmentManager(t, rt)
+	job := AttachmentJob{ID: "42", URL: "http://fake.invalid/42", Filename: "f.bin"}
+
+	first := m.Download(context.Background(), []AttachmentJob{job})
+	if len(first) != 1 || first[0].Err != nil {
+		t.Fatalf("unexpected result from first Download call: %+v", first)
+	}
+
+	second := m.Download(context.Background(), []AttachmentJob{job})
+	if len(second) != 1 || second[0].Err != nil {
+		t.Fatalf("unexpected result from second Download call: %+v", second)
+	}
+	if second[0].Path != first[0].Path {
+		t.Errorf("expected the already-seen id to resolve to the same path on a later call")
+	}
+	if rt.calls != 1 {
+		t.Errorf("expected no additional network call for an id already seen in an earlier Download call, got %d total calls", rt.calls)
+	}
+}
+
+func TestFetchToFile_ResumesViaRangeHeader(t *testing.T) {
+	const full = "hello, world this is the full body"
+	const alreadyHave = 7 // len("hello, ")
+
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		if gotRange != "" {
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(full[alreadyHave:]))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(full))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "attachment.bin")
+	if err := os.WriteFile(dest, []byte(full[:alreadyHave]), 0644); err != nil {
+		t.Fatalf("seeding partial file: %v", err)
+	}
+
+	m := &AttachmentManager{httpClient: server.Client()}
+	job := AttachmentJob{ID: "1", URL: server.URL, Filename: "attachment.bin", ExpectedSize: int64(len(full))}
+
+	n, err := m.fetchToFile(context.Background(), job, dest)
+	if err != nil {
+		t.Fatalf("fetchToFile: %v", err)
+	}
+	if n != int64(len(full)-alreadyHave) {
+		t.Errorf("expected %d resumed bytes, got %d", len(full)-alreadyHave, n)
+	}
+	if gotRange != fmt.Sprintf("bytes=%d-", alreadyHave) {
+		t.Errorf("expected Range header %q, got %q", fmt.Sprintf("bytes=%d-", alreadyHave), gotRange)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("reading result file: %v", err)
+	}
+	if string(got) != full {
+		t.Errorf("expected resumed file to equal the full body, got %q", got)
+	}
+}
+
+func TestFetchToFile_SkipsRequestWhenAlreadyComplete(t *testing.T) {
+	const full = "already downloaded in full"
+	dest := filepath.Join(t.TempDir(), "attachment.bin")
+	if err := os.WriteFile(dest, []byte(full), 0644); err != nil {
+		t.Fatalf("seeding complete file: %v", err)
+	}
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := &AttachmentManager{httpClient: server.Client()}
+	job := AttachmentJob{ID: "1", URL: server.URL, Filename: "attachment.bin", ExpectedSize: int64(len(full))}
+
+	n, err := m.fetchToFile(context.Background(), job, dest)
+	if err != nil {
+		t.Fatalf("fetchToFile: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected no bytes fetched for an already-complete file, got %d", n)
+	}
+	if called {
+		t.Error("expected no network request for a file that already matches ExpectedSize")
+	}
+}
@@ -0,0 +1,49 @@
+package tracker
+
+import "fmt"
+
+// ErrAPI indicates the Tracker API returned an HTTP status the exporter
+// doesn't know how to handle (anything other than 200, 429 or 503), or kept
+// answering 429/503 until the retry policy's MaxAttempts ran out.
+type ErrAPI struct {
+	Status int
+	URL    string
+}
+
+func (e ErrAPI) Error() string {
+	return fmt.Sprintf("unexpected API status %d for %s", e.Status, e.URL)
+}
+
+// ErrMalformedResponse indicates a response was missing, or had the wrong
+// type for, a field the exporter depends on.
+type ErrMalformedResponse struct {
+	Field string
+	Err   error
+}
+
+func (e ErrMalformedResponse) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("malformed response (field %q): %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("malformed response: missing or invalid field %q", e.Field)
+}
+
+func (e ErrMalformedResponse) Unwrap() error {
+	return e.Err
+}
+
+// ErrIO wraps a filesystem error encountered while reading or writing export
+// artifacts (cache.json, stats.json, attachments, ...).
+type ErrIO struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e ErrIO) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e ErrIO) Unwrap() error {
+	return e.Err
+}
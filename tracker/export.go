@@ -3,7 +3,6 @@ package tracker
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -20,56 +19,138 @@ import (
 
 var logger = log.New(os.Stdout, "", log.LstdFlags)
 
-func Export(ctx context.Context, trackerToken string, projectID string, outDir string) {
+// ExportOption customizes a single Export call.
+type ExportOption func(*exportConfig)
+
+type exportConfig struct {
+	retryPolicy RetryPolicy
+}
+
+// WithRetryPolicy overrides the default RetryPolicy Export uses for every
+// request it makes, letting callers (tests, or operators working against a
+// flakier mirror) tune retry counts and backoff without forking Export.
+func WithRetryPolicy(policy RetryPolicy) ExportOption {
+	return func(c *exportConfig) {
+		c.retryPolicy = policy
+	}
+}
+
+// Export walks a Tracker project and writes everything it finds to outDir.
+// Once an existing cache.json has loaded, it always flushes cache.json and
+// stats.json before returning, even when an unexpected API response cuts the
+// walk short, so a failed run still leaves a usable partial cache behind for
+// the next attempt. If loading cache.json fails, it returns that error
+// without writing anything.
+func Export(ctx context.Context, trackerToken string, projectID string, outDir string, opts ...ExportOption) error {
+	cfg := exportConfig{retryPolicy: defaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	client := resty.New()
 	client.SetBaseURL("https://www.pivotaltracker.com/services/v5")
 	client.Header.Set("X-TrackerToken", trackerToken)
 
 	client.SetPathParam("project_id", projectID)
 
+	progress := newProgressReporter()
+	stats := newStatistics()
 	d := Downloader{
-		client: client,
+		client:      client,
+		progress:    progress,
+		stats:       stats,
+		retryPolicy: cfg.retryPolicy,
 	}
 	cacheFile := filepath.Join(outDir, "cache.json")
-	d.load(cacheFile)
+	if err := d.load(cacheFile); err != nil {
+		return err
+	}
 
-	visitList := func(path string, keys PathKeys, fields string, downstream func([]genericJSON)) {
-		visit[[]genericJSON](ctx, &d, path, keys, fields, fetchSimple, downstream)
+	attachments := newAttachmentManager(client, outDir)
+	attachments.SetReporter(progress)
+
+	visitList := func(path string, keys PathKeys, fields string, downstream func([]genericJSON) error) error {
+		return visit[[]genericJSON](ctx, &d, path, keys, fields, makeSimpleFetcher[[]genericJSON](stats, d.retryPolicy), downstream)
 	}
-	_ = visitList
-	visitObject := func(path string, keys PathKeys, fields string, downstream func(genericJSON)) {
-		visit[genericJSON](ctx, &d, path, keys, fields, fetchSimple, downstream)
+	visitObject := func(path string, keys PathKeys, fields string, downstream func(genericJSON) error) error {
+		return visit[genericJSON](ctx, &d, path, keys, fields, makeSimpleFetcher[genericJSON](stats, d.retryPolicy), downstream)
 	}
-	_ = visitObject
 
 	pathkeys := PathKeys{}.withKey("project_id", projectID)
-	visitObject("/projects/{project_id}", pathkeys, "", nil)
-	d.visitPaginated(ctx, "/projects/{project_id}/activity", pathkeys, 10, "", nil)
-	visitList("/projects/{project_id}/labels", pathkeys, "", nil)
-	visitList("/projects/{project_id}/memberships", pathkeys, "", nil)
-	d.visitPaginated(ctx, "/projects/{project_id}/releases", pathkeys, 10, ":default,story_ids", nil)
-	d.visitPaginated(ctx, "/projects/{project_id}/iterations", pathkeys, 10, "", nil)
-	visitList("/projects/{project_id}/epics", pathkeys, ":default,comments(:default,file_attachments,google_attachments,attachment_ids)", foreach(func(item genericJSON) {
-		pathkeys := pathkeys.withKey("epic_id", getNumericKey(item, "id"))
-		d.handleCommentAttachments(item)
-		visitList("/projects/{project_id}/epics/{epic_id}/activity", pathkeys, "", nil)
-	}))
-	d.visitPaginated(ctx, "/projects/{project_id}/stories", pathkeys, 10, ":default,comments(:default,file_attachments,google_attachments,attachment_ids),owners(:default),reviews(:default),tasks(:default),transitions(:default),blockers(:default),labels(:default)", foreach(func(item genericJSON) {
-		pathkeys := pathkeys.withKey("story_id", getNumericKey(item, "id"))
-
-		d.handleCommentAttachments(item)
-		visitList("/projects/{project_id}/stories/{story_id}/activity", pathkeys, "", nil)
-	}))
+	steps := []func() error{
+		func() error { return visitObject("/projects/{project_id}", pathkeys, "", nil) },
+		func() error { return d.visitPaginated(ctx, "/projects/{project_id}/activity", pathkeys, 10, "", nil) },
+		func() error { return visitList("/projects/{project_id}/labels", pathkeys, "", nil) },
+		func() error { return visitList("/projects/{project_id}/memberships", pathkeys, "", nil) },
+		func() error {
+			return d.visitPaginated(ctx, "/projects/{project_id}/releases", pathkeys, 10, ":default,story_ids", nil)
+		},
+		func() error { return d.visitPaginated(ctx, "/projects/{project_id}/iterations", pathkeys, 10, "", nil) },
+		func() error {
+			return visitList("/projects/{project_id}/epics", pathkeys, ":default,comments(:default,file_attachments,google_attachments,attachment_ids)", foreach(func(item genericJSON) error {
+				epicID, err := getNumericKey(item, "id")
+				if err != nil {
+					return err
+				}
+				epicKeys := pathkeys.withKey("epic_id", epicID)
+				if err := handleCommentAttachments(ctx, attachments, item); err != nil {
+					return err
+				}
+				return visitList("/projects/{project_id}/epics/{epic_id}/activity", epicKeys, "", nil)
+			}))
+		},
+		func() error {
+			return d.visitPaginated(ctx, "/projects/{project_id}/stories", pathkeys, 10, ":default,comments(:default,file_attachments,google_attachments,attachment_ids),owners(:default),reviews(:default),tasks(:default),transitions(:default),blockers(:default),labels(:default)", foreach(func(item genericJSON) error {
+				storyID, err := getNumericKey(item, "id")
+				if err != nil {
+					return err
+				}
+				storyKeys := pathkeys.withKey("story_id", storyID)
+				if err := handleCommentAttachments(ctx, attachments, item); err != nil {
+					return err
+				}
+				return visitList("/projects/{project_id}/stories/{story_id}/activity", storyKeys, "", nil)
+			}))
+		},
+	}
+
+	var exportErr error
+	for _, step := range steps {
+		if exportErr = step(); exportErr != nil {
+			logger.Printf("export step failed, stopping early: %v", exportErr)
+			break
+		}
+	}
+
 	completionChecker.report()
-	d.save(cacheFile)
-	d.dumpCopies(outDir)
+
+	var flushErr error
+	recordFlushErr := func(err error) {
+		if err != nil && flushErr == nil {
+			flushErr = err
+		}
+	}
+	recordFlushErr(d.save(cacheFile))
+	recordFlushErr(d.dumpCopies(outDir))
+	recordFlushErr(attachments.WriteManifest(filepath.Join(outDir, "attachments", "manifest.json")))
+	progress.Finish()
+	recordFlushErr(stats.Write(filepath.Join(outDir, "stats.json")))
+	stats.PrintSummary()
+
+	if exportErr != nil {
+		return exportErr
+	}
+	return flushErr
 }
 
-func foreach(f func(item genericJSON)) func(itemList []genericJSON) {
-	return func(itemList []genericJSON) {
+func foreach(f func(item genericJSON) error) func(itemList []genericJSON) error {
+	return func(itemList []genericJSON) error {
 		for _, item := range itemList {
-			f(item)
+			if err := f(item); err != nil {
+				return err
+			}
 		}
+		return nil
 	}
 }
 
@@ -100,69 +181,92 @@ type DownloadedContent struct {
 	PathTemplate string
 	Keys         PathKeys
 	Data         any
+	// UpdatedAfter is the largest "updated_at" seen across Data, when Data is
+	// a list resource. For paginated resources the next run passes it back as
+	// the updated_after= query param so only changed items need to be
+	// re-fetched; unpaginated lists are always fetched in full.
+	UpdatedAfter string `json:"updated_after,omitempty"`
 }
 type Downloader struct {
 	downloadedData []*DownloadedContent
 	client         *resty.Client
+	progress       ProgressReporter
+	stats          *Statistics
+	retryPolicy    RetryPolicy
 }
 
-func (d *Downloader) visitPaginated(ctx context.Context, path string, keys PathKeys, pageLimit int, fields string, downstreamHandler func([]genericJSON)) {
-	visit[[]genericJSON](ctx, d, path, keys, fields, makePaginatedFetcher(pageLimit), downstreamHandler)
+func (d *Downloader) visitPaginated(ctx context.Context, path string, keys PathKeys, pageLimit int, fields string, downstreamHandler func([]genericJSON) error) error {
+	return visit[[]genericJSON](ctx, d, path, keys, fields, makePaginatedFetcher(pageLimit, d.progress, d.stats, d.retryPolicy), downstreamHandler)
 }
 
-type Fetcher[T any] func(ctx context.Context, req *resty.Request, path string) (T, error)
+// Fetcher performs the actual network fetch for one resource. prev is the
+// entry already in the cache for (path, keys), or nil on a first-ever visit;
+// a Fetcher may use it to compare against the fresh response and avoid
+// dirtying the cache when nothing changed (object resources) or to fetch
+// incrementally and merge (paginated list resources). changed reports
+// whether the returned data differs from prev.Data, so visit knows whether
+// the cache needs updating.
+type Fetcher[T any] func(ctx context.Context, req *resty.Request, path string, prev *DownloadedContent) (data T, changed bool, err error)
 
-func visit[T any](ctx context.Context, d *Downloader, path string, keys PathKeys, fields string, fetcher Fetcher[T], downstreamHandler func(resp T)) {
+func visit[T any](ctx context.Context, d *Downloader, path string, keys PathKeys, fields string, fetcher Fetcher[T], downstreamHandler func(resp T) error) error {
 	select {
 	case <-ctx.Done():
-		return
+		return ctx.Err()
 	default:
-
 	}
 
-	var resp T
-	isCached := false
+	var prev *DownloadedContent
 	for _, cached := range d.downloadedData {
 		if cached.PathTemplate == path && cached.Keys.equal(keys) {
-			recodeJsonAs(cached.Data, &resp)
-			isCached = true
+			prev = cached
 			completionChecker.observe(cached.PathTemplate)
 			break
 		}
 	}
-	if !isCached {
-		var err error
-		resp, err = fetcher(ctx, d.getRequest(keys, fields), path)
-		if err != nil {
-			logger.Println(err.Error())
-			return
-		}
 
-		d.addResult(path, keys, resp)
+	resp, changed, err := fetcher(ctx, d.getRequest(keys, fields), path, prev)
+	if err != nil {
+		logger.Println(err.Error())
+		return err
+	}
+	if changed {
+		d.addResult(path, keys, prev, resp)
 	}
+	d.progress.Done(path)
 
 	if downstreamHandler != nil {
-		downstreamHandler(resp)
+		return downstreamHandler(resp)
 	}
+	return nil
 }
 
-func recodeJsonAs[T any](data any, t *T) {
+// recodeJsonAs round-trips data (typically a DownloadedContent.Data loaded
+// back from cache.json as interface{}) through JSON into the concrete type T,
+// so a cached response can be compared against, or treated as, a fresh one.
+func recodeJsonAs[T any](data any, t *T) error {
 	marshalledContent, err := json.Marshal(data)
 	if err != nil {
-		panic(err)
+		return ErrMalformedResponse{Field: "cache", Err: err}
 	}
-	err = json.Unmarshal(marshalledContent, t)
-	if err != nil {
-		panic(err)
+	if err := json.Unmarshal(marshalledContent, t); err != nil {
+		return ErrMalformedResponse{Field: "cache", Err: err}
 	}
+	return nil
 }
 
-func (d *Downloader) addResult(requestTemplate string, keys PathKeys, response any) {
-	d.downloadedData = append(d.downloadedData, &DownloadedContent{
-		Keys:         keys,
-		PathTemplate: requestTemplate,
-		Data:         response,
-	})
+// addResult stores a fetcher's output, updating prev in place when one was
+// passed (so an incremental refresh replaces rather than duplicates the
+// cache entry) or appending a new entry otherwise.
+func (d *Downloader) addResult(requestTemplate string, keys PathKeys, prev *DownloadedContent, response any) {
+	entry := prev
+	if entry == nil {
+		entry = &DownloadedContent{Keys: keys, PathTemplate: requestTemplate}
+		d.downloadedData = append(d.downloadedData, entry)
+	}
+	entry.Data = response
+	if items, ok := response.([]genericJSON); ok {
+		entry.UpdatedAfter = maxUpdatedAt(items)
+	}
 }
 
 func (d *Downloader) getRequest(keys PathKeys, fields string) *resty.Request {
@@ -176,34 +280,41 @@ func (d *Downloader) getRequest(keys PathKeys, fields string) *resty.Request {
 	return req
 }
 
-func (d *Downloader) save(file string) {
-	saveAsJSON(d.downloadedData, file)
+func (d *Downloader) save(file string) error {
+	return saveAsJSON(d.downloadedData, file)
 }
 
-func saveAsJSON(data any, file string) {
+func saveAsJSON(data any, file string) error {
 	res, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		logger.Fatal(err)
+		return ErrMalformedResponse{Field: file, Err: err}
 	}
 
 	if err := os.WriteFile(file, res, 0644); err != nil {
-		logger.Fatal(err)
+		return ErrIO{Op: "write", Path: file, Err: err}
 	}
-
+	return nil
 }
 
-func (d *Downloader) load(file string) {
+// load populates the in-memory cache from a previous run's cache.json, if
+// one exists. A missing file is not an error: it just means this is the
+// first export of the project.
+func (d *Downloader) load(file string) error {
 	contents, err := os.ReadFile(file)
 	if err != nil {
-		return
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return ErrIO{Op: "read", Path: file, Err: err}
 	}
 
 	if err := json.Unmarshal(contents, &d.downloadedData); err != nil {
-		logger.Fatal(err)
+		return ErrMalformedResponse{Field: file, Err: err}
 	}
+	return nil
 }
 
-func (d *Downloader) dumpCopies(outDir string) {
+func (d *Downloader) dumpCopies(outDir string) error {
 	groups := lo.GroupBy(d.downloadedData, func(item *DownloadedContent) string {
 		return item.PathTemplate
 	})
@@ -214,29 +325,94 @@ func (d *Downloader) dumpCopies(outDir string) {
 		contents := lo.Map(group, func(content *DownloadedContent, _ int) any {
 			return content.Data
 		})
-		saveAsJSON(contents, filepath.Join(outDir, fileName))
+		if err := saveAsJSON(contents, filepath.Join(outDir, fileName)); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (d *Downloader) handleCommentAttachments(itemWithComments genericJSON) {
+// handleCommentAttachments downloads every file attachment referenced by the
+// comments on itemWithComments (a story or epic) and rewrites each attachment
+// entry in place with a "local_path" pointing at the downloaded copy.
+func handleCommentAttachments(ctx context.Context, attachments *AttachmentManager, itemWithComments genericJSON) error {
+	var jobs []AttachmentJob
+	attachmentsByID := map[string][]any{}
+
 	for _, comment := range getListFieldValue(itemWithComments, "comments") {
 		for _, attachment := range getListFieldValue(comment, "file_attachments") {
 			filename, ok := getFieldValue(attachment, "filename")
 			if !ok {
-				log.Fatalf("could not get attachment filename")
+				return ErrMalformedResponse{Field: "file_attachments.filename"}
 			}
 			downloadUrl, ok := getFieldValue(attachment, "download_url")
 			if !ok {
-				log.Fatalf("could not get attachment download url")
+				return ErrMalformedResponse{Field: "file_attachments.download_url"}
 			}
 			attachmentID, ok := getFieldValue(attachment, "id")
 			if !ok {
-				log.Fatalf("could not get attachment id")
+				return ErrMalformedResponse{Field: "file_attachments.id"}
+			}
+			attachmentIDFloat, ok := attachmentID.(float64)
+			if !ok {
+				return ErrMalformedResponse{Field: "file_attachments.id"}
+			}
+			attachmentIDStr := fmt.Sprintf("%d", int64(attachmentIDFloat))
+
+			var expectedSize int64
+			if size, ok := getFieldValue(attachment, "size"); ok {
+				sizeFloat, ok := size.(float64)
+				if !ok {
+					return ErrMalformedResponse{Field: "file_attachments.size"}
+				}
+				expectedSize = int64(sizeFloat)
+			}
+
+			downloadUrlStr, ok := downloadUrl.(string)
+			if !ok {
+				return ErrMalformedResponse{Field: "file_attachments.download_url"}
 			}
-			attachmentIDStr := fmt.Sprintf("%d", int64(attachmentID.(float64)))
-			logger.Printf("attachment detected: %q %q %v", filename, downloadUrl, attachmentIDStr)
+			filenameStr, ok := filename.(string)
+			if !ok {
+				return ErrMalformedResponse{Field: "file_attachments.filename"}
+			}
+
+			attachmentsByID[attachmentIDStr] = append(attachmentsByID[attachmentIDStr], attachment)
+			jobs = append(jobs, AttachmentJob{
+				ID:           attachmentIDStr,
+				URL:          downloadUrlStr,
+				Filename:     filenameStr,
+				ExpectedSize: expectedSize,
+			})
 		}
 	}
+
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	// Individual attachment failures are logged, not propagated: one broken
+	// download shouldn't abort the whole export.
+	for _, result := range attachments.Download(ctx, jobs) {
+		if result.Err != nil {
+			logger.Printf("attachment %s failed: %v", result.Job.ID, result.Err)
+			continue
+		}
+		for _, entry := range attachmentsByID[result.Job.ID] {
+			setFieldValue(entry, "local_path", result.Path)
+		}
+	}
+	return nil
+}
+
+// setFieldValue mutates obj (expected to be a map underlying genericJSON) in
+// place, the mirror image of getFieldValue.
+func setFieldValue(obj any, key string, value any) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Map {
+		return
+	}
+	v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
 }
 
 type completionEntry struct {
@@ -320,8 +496,12 @@ func getListFieldValue(obj any, key string) []any {
 	return listValue
 }
 
-func makePaginatedFetcher(limit int) func(ctx context.Context, req *resty.Request, path string) ([]genericJSON, error) {
-	return func(ctx context.Context, req *resty.Request, path string) ([]genericJSON, error) {
+// makePaginatedFetcher fetches a list resource page by page. When prev holds
+// a cached list with a non-empty UpdatedAfter, the request is scoped with
+// updated_after= so only items changed since the last run come back; those
+// are merged into the cached list by id rather than replacing it wholesale.
+func makePaginatedFetcher(limit int, progress ProgressReporter, stats *Statistics, policy RetryPolicy) Fetcher[[]genericJSON] {
+	return func(ctx context.Context, req *resty.Request, path string, prev *DownloadedContent) ([]genericJSON, bool, error) {
 		type paginatedResponse struct {
 			Pagination struct {
 				Total    int `json:"total"`
@@ -332,51 +512,102 @@ func makePaginatedFetcher(limit int) func(ctx context.Context, req *resty.Reques
 			Data []genericJSON `json:"data"`
 		}
 
+		var cached []genericJSON
+		if prev != nil {
+			if err := recodeJsonAs(prev.Data, &cached); err != nil {
+				return nil, false, err
+			}
+			if prev.UpdatedAfter != "" {
+				req = req.SetQueryParam("updated_after", prev.UpdatedAfter)
+			}
+		}
+
 		var result []genericJSON
 		done := false
 		for !done {
 			respBody := paginatedResponse{}
 
-			req := req.
+			pageReq := req.
 				SetContext(ctx).
 				SetResult(&respBody).
 				SetQueryParam("limit", fmt.Sprintf("%d", limit)).
 				SetQueryParam("offset", fmt.Sprintf("%d", len(result))).
 				SetQueryParam("envelope", "true")
 
-			err := getWithRetries(ctx, req, path)
+			err := getWithRetries(ctx, pageReq, path, stats, policy)
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
 
 			result = append(result, respBody.Data...)
-			logger.Printf("fetched %d/%d\n", len(result), respBody.Pagination.Total)
+			progress.Update(path, len(result), respBody.Pagination.Total, respBody.Pagination.Returned)
 			done = len(result) == respBody.Pagination.Total
 		}
-		return result, nil
+
+		if prev != nil && len(result) == 0 {
+			return cached, false, nil
+		}
+		merged, err := mergeByID(cached, result)
+		if err != nil {
+			return nil, false, err
+		}
+		return merged, true, nil
 	}
 }
 
-func fetchSimple[T any](ctx context.Context, req *resty.Request, path string) (T, error) {
-	var resp T
-	req.SetContext(ctx)
-	req.SetResult(&resp)
-	err := getWithRetries(ctx, req, path)
-	return resp, err
+// makeSimpleFetcher fetches a single object or unpaginated list resource. It
+// always makes the request, rather than trusting a cached copy forever, so a
+// resource that genuinely changed between runs is picked up; it only reports
+// changed=false (and counts a cache hit) when the response is identical to
+// what prev already held, so an unchanged resource doesn't needlessly dirty
+// the cache on every run.
+func makeSimpleFetcher[T any](stats *Statistics, policy RetryPolicy) Fetcher[T] {
+	return func(ctx context.Context, req *resty.Request, path string, prev *DownloadedContent) (T, bool, error) {
+		var resp T
+		req.SetContext(ctx)
+		req.SetResult(&resp)
+		if err := getWithRetries(ctx, req, path, stats, policy); err != nil {
+			return resp, false, err
+		}
+
+		if prev != nil {
+			var cached T
+			if err := recodeJsonAs(prev.Data, &cached); err != nil {
+				return resp, false, err
+			}
+			if reflect.DeepEqual(cached, resp) {
+				stats.RecordCacheHit(path)
+				return resp, false, nil
+			}
+		}
+		return resp, true, nil
+	}
 }
 
-func getWithRetries(ctx context.Context, req *resty.Request, path string) error {
-	req.SetContext(ctx)
-	var retryTimeout = time.Duration(0)
-	for {
-		req.SetContext(ctx)
+// getWithRetries issues req against path, retrying according to policy. Each
+// attempt gets its own bounded context.WithTimeout derived from ctx, so a
+// hung TCP connection can't stall the whole export past PerAttemptTimeout.
+func getWithRetries(ctx context.Context, req *resty.Request, path string, stats *Statistics, policy RetryPolicy) error {
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, policy.PerAttemptTimeout)
+		req.SetContext(attemptCtx)
+		start := time.Now()
 		resp, err := req.Get(path)
+		cancel()
+
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return err
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if attempt >= policy.MaxAttempts {
+				return fmt.Errorf("giving up on %s after %d attempts: %w", path, attempt, err)
+			}
+			wait := policy.backoff(attempt)
+			logger.Printf("API call to %s failed (%v), retrying in %s (attempt %d/%d)", path, err, wait, attempt, policy.MaxAttempts)
+			stats.RecordRetry(path)
+			if !sleepOrCancel(ctx, wait) {
+				return ctx.Err()
 			}
-			log.Println("API call failed, will retry")
-			time.Sleep(5 * time.Second)
 			continue
 		}
 		logger.Printf("finished call: %s", req.URL)
@@ -385,23 +616,46 @@ func getWithRetries(ctx context.Context, req *resty.Request, path string) error
 
 		switch resp.StatusCode() {
 		case http.StatusOK:
+			stats.RecordRequest(path, time.Since(start), int64(len(resp.Body())))
 			return nil
-		case http.StatusTooManyRequests:
-			retryTimeout += 5 * time.Second
-			logger.Printf("server complaining about too many requests, sleeping for %s", retryTimeout)
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			stats.RecordTooManyRequests(path)
+			if attempt >= policy.MaxAttempts {
+				return ErrAPI{Status: resp.StatusCode(), URL: req.RawRequest.URL.String()}
+			}
+			wait := policy.backoff(attempt)
+			if ra := policy.retryAfter(resp.Header()); ra > 0 {
+				wait = ra
+			}
+			logger.Printf("server responded %d for %s, retrying in %s (attempt %d/%d)", resp.StatusCode(), path, wait, attempt, policy.MaxAttempts)
+			stats.RecordRetry(path)
+			if !sleepOrCancel(ctx, wait) {
+				return ctx.Err()
+			}
 		default:
-			logger.Fatalf("unexpected status (%s): %v", req.RawRequest.URL.String(), resp.Status())
+			return ErrAPI{Status: resp.StatusCode(), URL: req.RawRequest.URL.String()}
 		}
+	}
+}
 
-		select {
-		case <-time.After(retryTimeout):
-			continue
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+// sleepOrCancel waits for d, returning false early if ctx is canceled first.
+func sleepOrCancel(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 
-func getNumericKey(obj genericJSON, key string) string {
-	return fmt.Sprintf("%d", int64(obj[key].(float64)))
+func getNumericKey(obj genericJSON, key string) (string, error) {
+	value, ok := getFieldValue(obj, key)
+	if !ok {
+		return "", ErrMalformedResponse{Field: key}
+	}
+	f, ok := value.(float64)
+	if !ok {
+		return "", ErrMalformedResponse{Field: key}
+	}
+	return fmt.Sprintf("%d", int64(f)), nil
 }
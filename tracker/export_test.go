@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// fakeRoundTripper replays a fixed sequence of responses, one per call to
+// RoundTrip, so getWithRetries can be exercised without any real network
+// access. The last response is reused for any call past the end of the list.
+type fakeRoundTripper struct {
+	responses []fakeResponse
+	calls     int
+}
+
+type fakeResponse struct {
+	status int
+	header http.Header
+	body   string
+	err    error
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := f.calls
+	if i >= len(f.responses) {
+		i = len(f.responses) - 1
+	}
+	f.calls++
+
+	r := f.responses[i]
+	if r.err != nil {
+		return nil, r.err
+	}
+	header := r.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: r.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(r.body)),
+		Request:    req,
+	}, nil
+}
+
+// testRetryPolicy keeps retries fast and deterministic so the tests don't
+// spend real wall-clock time waiting out backoff.
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        time.Millisecond,
+		Multiplier:        1,
+		Jitter:            false,
+		PerAttemptTimeout: time.Second,
+		RespectRetryAfter: true,
+	}
+}
+
+func newFakeClient(rt http.RoundTripper) *resty.Client {
+	client := resty.New()
+	client.SetBaseURL("http://fake.invalid")
+	client.SetTransport(rt)
+	return client
+}
+
+func TestGetWithRetries_SucceedsOnFirstAttempt(t *testing.T) {
+	rt := &fakeRoundTripper{responses: []fakeResponse{{status: http.StatusOK, body: "{}"}}}
+	client := newFakeClient(rt)
+	stats := newStatistics()
+
+	err := getWithRetries(context.Background(), client.R(), "/widgets", stats, testRetryPolicy())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := stats.endpoints["/widgets"].Requests; got != 1 {
+		t.Errorf("expected 1 recorded request, got %d", got)
+	}
+}
+
+func TestGetWithRetries_RetriesOnServiceUnavailableThenSucceeds(t *testing.T) {
+	rt := &fakeRoundTripper{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable, body: ""},
+		{status: http.StatusOK, body: "{}"},
+	}}
+	client := newFakeClient(rt)
+	stats := newStatistics()
+
+	err := getWithRetries(context.Background(), client.R(), "/widgets", stats, testRetryPolicy())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := stats.endpoints["/widgets"].TooManyRequests; got != 1 {
+		t.Errorf("expected 1 recorded 503, got %d", got)
+	}
+	if got := stats.endpoints["/widgets"].Requests; got != 1 {
+		t.Errorf("expected 1 successful request after the retry, got %d", got)
+	}
+}
+
+func TestGetWithRetries_UnexpectedStatusReturnsErrAPI(t *testing.T) {
+	rt := &fakeRoundTripper{responses: []fakeResponse{{status: http.StatusNotFound, body: ""}}}
+	client := newFakeClient(rt)
+	stats := newStatistics()
+
+	err := getWithRetries(context.Background(), client.R(), "/widgets", stats, testRetryPolicy())
+
+	var apiErr ErrAPI
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected ErrAPI, got %v", err)
+	}
+	if apiErr.Status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, apiErr.Status)
+	}
+}
+
+func TestGetWithRetries_GivesUpAfterMaxAttempts(t *testing.T) {
+	rt := &fakeRoundTripper{responses: []fakeResponse{{status: http.StatusServiceUnavailable, body: ""}}}
+	client := newFakeClient(rt)
+	stats := newStatistics()
+	policy := testRetryPolicy()
+
+	err := getWithRetries(context.Background(), client.R(), "/widgets", stats, policy)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	var apiErr ErrAPI
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected ErrAPI, got %v", err)
+	}
+	if got := stats.endpoints["/widgets"].TooManyRequests; got != policy.MaxAttempts {
+		t.Errorf("expected %d recorded 503s, got %d", policy.MaxAttempts, got)
+	}
+}
+
+func TestDownloaderLoad_MalformedCacheReturnsErrMalformedResponse(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(file, []byte("not valid json"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	var d Downloader
+	err := d.load(file)
+
+	var malformed ErrMalformedResponse
+	if !errors.As(err, &malformed) {
+		t.Fatalf("expected ErrMalformedResponse, got %v", err)
+	}
+	if malformed.Field != file {
+		t.Errorf("expected field %q, got %q", file, malformed.Field)
+	}
+}
+
+func TestGetNumericKey_MalformedFieldReturnsErrMalformedResponse(t *testing.T) {
+	var item genericJSON
+	if err := json.Unmarshal([]byte(`{"id": "not-a-number"}`), &item); err != nil {
+		t.Fatalf("failed to build fixture: %v", err)
+	}
+
+	_, err := getNumericKey(item, "id")
+
+	var malformed ErrMalformedResponse
+	if !errors.As(err, &malformed) {
+		t.Fatalf("expected ErrMalformedResponse, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package tracker
+
+// mergeByID combines a previously cached list of items with newly fetched
+// ones, keyed by the Tracker "id" field. Items returned by the new fetch win
+// on conflict; ids only present in the cache are kept as-is; never-before
+// seen ids are appended in the order they were returned.
+func mergeByID(cached, fresh []genericJSON) ([]genericJSON, error) {
+	byID := make(map[string]genericJSON, len(cached)+len(fresh))
+	order := make([]string, 0, len(cached)+len(fresh))
+
+	for _, item := range cached {
+		id, err := getNumericKey(item, "id")
+		if err != nil {
+			return nil, err
+		}
+		if _, seen := byID[id]; !seen {
+			order = append(order, id)
+		}
+		byID[id] = item
+	}
+	for _, item := range fresh {
+		id, err := getNumericKey(item, "id")
+		if err != nil {
+			return nil, err
+		}
+		if _, seen := byID[id]; !seen {
+			order = append(order, id)
+		}
+		byID[id] = item
+	}
+
+	merged := make([]genericJSON, len(order))
+	for i, id := range order {
+		merged[i] = byID[id]
+	}
+	return merged, nil
+}
+
+// maxUpdatedAt returns the largest "updated_at" value found across items.
+// Tracker renders updated_at as RFC3339, so lexicographic comparison agrees
+// with chronological comparison.
+func maxUpdatedAt(items []genericJSON) string {
+	var max string
+	for _, item := range items {
+		value, ok := getFieldValue(item, "updated_at")
+		if !ok {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if s > max {
+			max = s
+		}
+	}
+	return max
+}
@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func jsonItem(id int, updatedAt string) genericJSON {
+	item := genericJSON{"id": float64(id)}
+	if updatedAt != "" {
+		item["updated_at"] = updatedAt
+	}
+	return item
+}
+
+func TestMergeByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		cached  []genericJSON
+		fresh   []genericJSON
+		want    []genericJSON
+		wantErr bool
+	}{
+		{
+			name:   "fresh wins on id conflict",
+			cached: []genericJSON{jsonItem(1, "2020-01-01T00:00:00Z")},
+			fresh:  []genericJSON{jsonItem(1, "2020-06-01T00:00:00Z")},
+			want:   []genericJSON{jsonItem(1, "2020-06-01T00:00:00Z")},
+		},
+		{
+			name:   "cache-only ids are retained",
+			cached: []genericJSON{jsonItem(1, ""), jsonItem(2, "")},
+			fresh:  nil,
+			want:   []genericJSON{jsonItem(1, ""), jsonItem(2, "")},
+		},
+		{
+			name:   "new ids are appended in fresh's order",
+			cached: []genericJSON{jsonItem(1, "")},
+			fresh:  []genericJSON{jsonItem(3, ""), jsonItem(2, "")},
+			want:   []genericJSON{jsonItem(1, ""), jsonItem(3, ""), jsonItem(2, "")},
+		},
+		{
+			name:    "malformed id propagates an error",
+			cached:  []genericJSON{{"id": "not-a-number"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeByID(tt.cached, tt.fresh)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxUpdatedAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []genericJSON
+		want  string
+	}{
+		{
+			name:  "picks the lexicographically (and chronologically) largest RFC3339 timestamp",
+			items: []genericJSON{jsonItem(1, "2020-01-01T00:00:00Z"), jsonItem(2, "2021-06-15T12:00:00Z"), jsonItem(3, "2020-12-31T23:59:59Z")},
+			want:  "2021-06-15T12:00:00Z",
+		},
+		{
+			name:  "ignores items missing updated_at",
+			items: []genericJSON{jsonItem(1, ""), jsonItem(2, "2020-01-01T00:00:00Z")},
+			want:  "2020-01-01T00:00:00Z",
+		},
+		{
+			name:  "ignores a non-string updated_at",
+			items: []genericJSON{{"id": float64(1), "updated_at": 12345.0}, jsonItem(2, "2020-01-01T00:00:00Z")},
+			want:  "2020-01-01T00:00:00Z",
+		},
+		{
+			name:  "empty input yields empty string",
+			items: nil,
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxUpdatedAt(tt.items); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
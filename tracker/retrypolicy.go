@@ -0,0 +1,55 @@
+package tracker
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how getWithRetries behaves when a request fails
+// outright or the server asks it to back off.
+type RetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Multiplier        float64
+	Jitter            bool
+	PerAttemptTimeout time.Duration
+	RespectRetryAfter bool
+}
+
+// defaultRetryPolicy is used by Export unless the caller passes WithRetryPolicy.
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       10,
+		InitialBackoff:    time.Second,
+		MaxBackoff:        time.Minute,
+		Multiplier:        2,
+		Jitter:            true,
+		PerAttemptTimeout: 30 * time.Second,
+		RespectRetryAfter: true,
+	}
+}
+
+// backoff returns how long to wait after the given attempt (1-indexed) has
+// failed, applying full-jitter exponential backoff per policy.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if !p.Jitter || wait <= 0 {
+		return time.Duration(wait)
+	}
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// retryAfter honors RespectRetryAfter by parsing the header on 429/503
+// responses; it returns 0 when the policy or header don't apply.
+func (p RetryPolicy) retryAfter(header http.Header) time.Duration {
+	if !p.RespectRetryAfter {
+		return 0
+	}
+	return parseRetryAfter(header.Get("Retry-After"))
+}
@@ -0,0 +1,115 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EndpointStats accumulates counters for a single path template across an
+// export run.
+type EndpointStats struct {
+	Requests        int           `json:"requests"`
+	CacheHits       int           `json:"cache_hits"`
+	BytesDownloaded int64         `json:"bytes_downloaded"`
+	Retries         int           `json:"retries"`
+	TooManyRequests int           `json:"too_many_requests"`
+	TotalLatency    time.Duration `json:"total_latency_ns"`
+}
+
+// AverageLatency is the mean round-trip time of successful requests against
+// this endpoint.
+func (e *EndpointStats) AverageLatency() time.Duration {
+	if e.Requests == 0 {
+		return 0
+	}
+	return e.TotalLatency / time.Duration(e.Requests)
+}
+
+// Statistics tracks per-endpoint request counters for a single export run,
+// so a run can be diffed against another or fed into CI to spot regressions.
+type Statistics struct {
+	mu        sync.Mutex
+	start     time.Time
+	endpoints map[string]*EndpointStats
+}
+
+func newStatistics() *Statistics {
+	return &Statistics{start: time.Now(), endpoints: map[string]*EndpointStats{}}
+}
+
+func (s *Statistics) endpoint(path string) *EndpointStats {
+	e, ok := s.endpoints[path]
+	if !ok {
+		e = &EndpointStats{}
+		s.endpoints[path] = e
+	}
+	return e
+}
+
+// RecordCacheHit notes that the API response for path was identical to what
+// cache.json already held, so the cache entry was left untouched.
+func (s *Statistics) RecordCacheHit(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(path).CacheHits++
+}
+
+// RecordRequest notes a successful API call against path.
+func (s *Statistics) RecordRequest(path string, latency time.Duration, bytesDownloaded int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.endpoint(path)
+	e.Requests++
+	e.TotalLatency += latency
+	e.BytesDownloaded += bytesDownloaded
+}
+
+// RecordRetry notes that a request against path had to be retried, whether
+// due to a transport error or a 429.
+func (s *Statistics) RecordRetry(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(path).Retries++
+}
+
+// RecordTooManyRequests notes a 429 or 503 response from path.
+func (s *Statistics) RecordTooManyRequests(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoint(path).TooManyRequests++
+}
+
+// statisticsReport is the JSON-serializable shape written to stats.json.
+type statisticsReport struct {
+	Endpoints map[string]*EndpointStats `json:"endpoints"`
+	WallTime  time.Duration             `json:"wall_time_ns"`
+}
+
+// Write persists the current counters to file as stats.json.
+func (s *Statistics) Write(file string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return saveAsJSON(statisticsReport{Endpoints: s.endpoints, WallTime: time.Since(s.start)}, file)
+}
+
+// PrintSummary renders a human-readable table, one row per endpoint, sorted
+// alphabetically so repeated runs diff cleanly.
+func (s *Statistics) PrintSummary() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, 0, len(s.endpoints))
+	for p := range s.endpoints {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	fmt.Printf("%-55s %6s %6s %12s %7s %5s %10s\n", "endpoint", "reqs", "cached", "bytes", "retries", "429s", "avg")
+	for _, p := range paths {
+		e := s.endpoints[p]
+		fmt.Printf("%-55s %6d %6d %12d %7d %5d %10s\n", p, e.Requests, e.CacheHits, e.BytesDownloaded, e.Retries, e.TooManyRequests, e.AverageLatency().Round(time.Millisecond))
+	}
+	fmt.Printf("total wall time: %s\n", time.Since(s.start).Round(time.Second))
+}